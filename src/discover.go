@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"snmp_mcp_server/config"
+)
+
+type DiscoverParams struct {
+	Auth   string `json:"auth" jsonschema:"Authorization"`
+	Target string `json:"target" jsonschema:"Target IP or hostname"`
+}
+
+// discoverOIDs are the well-known OIDs probed to build a DeviceProfile.
+var discoverOIDs = []string{
+	".1.3.6.1.2.1.1.1.0",          // sysDescr.0
+	".1.3.6.1.2.1.1.2.0",          // sysObjectID.0
+	".1.3.6.1.2.1.1.3.0",          // sysUpTime.0
+	".1.3.6.1.2.1.1.5.0",          // sysName.0
+	".1.3.6.1.2.1.47.1.1.1.1.2.1", // entPhysicalDescr.1
+	".1.3.6.1.2.1.2.1.0",          // ifNumber.0
+}
+
+// DeviceProfile is the normalized result of probing a target.
+type DeviceProfile struct {
+	SysDescr      string   `json:"sys_descr,omitempty"`
+	SysObjectID   string   `json:"sys_object_id,omitempty"`
+	SysName       string   `json:"sys_name,omitempty"`
+	SysUptime     string   `json:"sys_uptime,omitempty"`
+	EntPhysDescr  string   `json:"ent_physical_descr,omitempty"`
+	IfCount       int64    `json:"if_count,omitempty"`
+	Vendor        string   `json:"vendor,omitempty"`
+	Module        string   `json:"module,omitempty"`
+	SuggestedWalk []string `json:"suggested_walk_roots,omitempty"`
+}
+
+func discoverHandler(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[DiscoverParams]) (*mcp.CallToolResultFor[any], error) {
+	g, err := NewGoSNMP(params.Arguments.Auth, params.Arguments.Target)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snmp client: '%w'", err)
+	}
+
+	slog.Debug("Connect()", "target", g.Target, "version", g.Version)
+	if err := g.Connect(); err != nil {
+		slog.Error("Connect()", "target", g.Target, "version", g.Version, "error", err)
+		return nil, fmt.Errorf("failed connecting to target %s: %s", g.Target, err)
+	}
+	defer g.Conn.Close()
+
+	slog.Debug("Get()", "target", g.Target, "OID(s)", discoverOIDs)
+	res, err := g.Get(discoverOIDs)
+	if err != nil {
+		slog.Error("Get()", "target", g.Target, "version", g.Version, "error", err)
+		return nil, fmt.Errorf("failed probing target %s: %s", g.Target, err)
+	}
+
+	profile := &DeviceProfile{}
+	var sysObjectID string
+	for _, pdu := range res.Variables {
+		if pdu.Type == gosnmp.NoSuchObject || pdu.Type == gosnmp.NoSuchInstance {
+			continue
+		}
+		switch pdu.Name {
+		case ".1.3.6.1.2.1.1.1.0":
+			profile.SysDescr = formatPDUValue(pdu)
+		case ".1.3.6.1.2.1.1.2.0":
+			if oid, ok := pdu.Value.(string); ok {
+				sysObjectID = strings.TrimPrefix(oid, ".")
+				profile.SysObjectID = M.Translate(sysObjectID)
+			}
+		case ".1.3.6.1.2.1.1.5.0":
+			profile.SysName = formatPDUValue(pdu)
+		case ".1.3.6.1.2.1.1.3.0":
+			profile.SysUptime = formatPDUValue(pdu)
+		case ".1.3.6.1.2.1.47.1.1.1.1.2.1":
+			profile.EntPhysDescr = formatPDUValue(pdu)
+		case ".1.3.6.1.2.1.2.1.0":
+			profile.IfCount = gosnmp.ToBigInt(pdu.Value).Int64()
+		}
+	}
+
+	if p := matchProfile(sysObjectID); p != nil {
+		profile.Vendor = p.Vendor
+		profile.Module = p.Module
+		profile.SuggestedWalk = p.WalkRoots
+	}
+
+	out, err := json.MarshalIndent(profile, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to render device profile: %w", err)
+	}
+
+	return &mcp.CallToolResultFor[any]{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(out)}},
+	}, nil
+}
+
+// matchProfile returns the configured Profile whose SysObjectIDPrefix is
+// the longest prefix of sysObjectID, or nil if none match.
+func matchProfile(sysObjectID string) *config.Profile {
+	var best *config.Profile
+	for i, p := range C.Profiles {
+		prefix := strings.TrimPrefix(p.SysObjectIDPrefix, ".")
+		if sysObjectID != prefix && !strings.HasPrefix(sysObjectID, prefix+".") {
+			continue
+		}
+		if best == nil || len(prefix) > len(strings.TrimPrefix(best.SysObjectIDPrefix, ".")) {
+			best = &C.Profiles[i]
+		}
+	}
+	return best
+}
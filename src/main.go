@@ -1,127 +1,246 @@
-package main
-
-import (
-	"context"
-	"flag"
-	"fmt"
-	"log/slog"
-	"net"
-	"net/http"
-	"os"
-	"runtime/debug"
-	"snmp_mcp_server/config"
-	"strconv"
-	"strings"
-	"sync"
-
-	"github.com/gosnmp/gosnmp"
-	"github.com/modelcontextprotocol/go-sdk/mcp"
-)
-
-var (
-	C *config.Config
-
-	// Version returns the version of the mcp-net-snmp binary.
-	// It uses runtime/debug to fetch version information from the build, returning "(devel)" for local development builds.
-	// The version is computed once and cached for performance.
-	Version = sync.OnceValue(func() string {
-		// Default version string returned by `runtime/debug` if built
-		// from the source repository rather than with `go install`.
-		v := "(devel)"
-		if bi, ok := debug.ReadBuildInfo(); ok && bi.Main.Version != "" {
-			v = bi.Main.Version
-		}
-		return v
-	})
-)
-
-func parseLevel(level string) slog.Level {
-	var l slog.Level
-	if err := l.UnmarshalText([]byte(level)); err != nil {
-		return slog.LevelInfo
-	}
-	return l
-}
-
-func main() {
-	configFile := flag.String("config-file", "net-snmp.yml", "Path to configuration file.")
-	expandEnvVars := flag.Bool("config-expand-environment-variables", false, "Expand environment variables to source secrets")
-
-	transport := flag.String("transport", "stdio", "Transport type (stdio or http)")
-	addr := flag.String("transport-address", "localhost:8000", "The host and port to start the streamable-http server on")
-	//endpointPath := flag.String("transport-address-path", "/mcp", "Endpoint path for the streamable-http server")
-	logLevel := flag.String("log-level", "info", "Log level (debug, info, warn, error)")
-	flag.Parse()
-
-	conf, err := config.LoadFile(*configFile, *expandEnvVars)
-
-	if err != nil {
-		slog.Error("failed parsing config file", "error", err)
-		os.Exit(1)
-	}
-
-	C = conf
-
-	if err := run(*transport, *addr, parseLevel(*logLevel)); err != nil {
-		panic(err)
-	}
-}
-
-func run(transport, addr string, logLevel slog.Level) error {
-	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel})))
-	server := mcp.NewServer(&mcp.Implementation{Name: "net-snmp-tools", Version: Version()}, &mcp.ServerOptions{})
-
-	mcp.AddTool(server, &mcp.Tool{Name: "net_snmp_get", Description: "The net_snmp_get command is used to retrieve the value of a specific OID (Object Identifier) from an SNMP-enabled device. It performs a single request to fetch the value of one or more explicitly specified OIDs, and is ideal when you know exactly what piece of data you're querying."}, getHandler)
-	mcp.AddTool(server, &mcp.Tool{Name: "net_snmp_walk", Description: "The net_snmp_walk command is used to recursively retrieve a subtree of OIDs from an SNMP agent. It starts from a given root OID and walks down the tree, returning all OIDs and their values beneath it. This is useful for exploring available SNMP data or retrieving entire tables (e.g., interface lists, ARP tables)."}, walkHandler)
-
-	if transport == "http" {
-		handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server {
-			return server
-		}, nil)
-		slog.Info("Starting Net-SNMP MCP server using StreamableHTTP transport", "version", Version(), "address", addr)
-		return http.ListenAndServe(addr, handler)
-	} else {
-		slog.Info("Starting Net-SNMP MCP server using stdio transport", "version", Version())
-		return server.Run(context.Background(), mcp.NewStdioTransport())
-	}
-}
-
-func NewGoSNMP(auth string, target string) (*gosnmp.GoSNMP, error) {
-	transport := "udp"
-	if s := strings.SplitN(target, "://", 2); len(s) == 2 {
-		transport = s[0]
-		target = s[1]
-	}
-	port := uint16(161)
-	if host, _port, err := net.SplitHostPort(target); err == nil {
-		target = host
-		p, err := strconv.Atoi(_port)
-		if err != nil {
-			return nil, fmt.Errorf("failed converting port number to int for target %q: %w", target, err)
-		}
-		port = uint16(p)
-	}
-
-	g := &gosnmp.GoSNMP{
-		ExponentialTimeout: true,
-		MaxOids:            gosnmp.MaxOids,
-		Port:               port,
-		Retries:            *C.Options.Retries,
-		Target:             target,
-		Timeout:            C.Options.Timeout,
-		Transport:          transport,
-	}
-
-	if C.Options.AllowNonIncreasingOIDs {
-		g.AppOpts = map[string]any{
-			"c": true,
-		}
-	}
-
-	cauth, authOk := C.Auths[auth]
-	if authOk {
-		cauth.ConfigureSNMP(g, "")
-	}
-
-	return g, nil
-}
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"snmp_mcp_server/config"
+	"snmp_mcp_server/mib"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+var (
+	C *config.Config
+
+	// M is the MIB store used to translate between numeric OIDs and
+	// symbolic names. If Options.MibDirs is empty or lists only missing
+	// directories, M is an empty store seeded with just the well-known
+	// SMI roots; any other MIB load error stops the server at startup.
+	M *mib.Store
+
+	// Version returns the version of the mcp-net-snmp binary.
+	// It uses runtime/debug to fetch version information from the build, returning "(devel)" for local development builds.
+	// The version is computed once and cached for performance.
+	Version = sync.OnceValue(func() string {
+		// Default version string returned by `runtime/debug` if built
+		// from the source repository rather than with `go install`.
+		v := "(devel)"
+		if bi, ok := debug.ReadBuildInfo(); ok && bi.Main.Version != "" {
+			v = bi.Main.Version
+		}
+		return v
+	})
+)
+
+func parseLevel(level string) slog.Level {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return slog.LevelInfo
+	}
+	return l
+}
+
+func main() {
+	configFile := flag.String("config-file", "net-snmp.yml", "Path to configuration file.")
+	expandEnvVars := flag.Bool("config-expand-environment-variables", false, "Expand environment variables to source secrets")
+
+	transport := flag.String("transport", "stdio", "Transport type (stdio or http)")
+	addr := flag.String("transport-address", "localhost:8000", "The host and port to start the streamable-http server on")
+	//endpointPath := flag.String("transport-address-path", "/mcp", "Endpoint path for the streamable-http server")
+	logLevel := flag.String("log-level", "info", "Log level (debug, info, warn, error)")
+	flag.Parse()
+
+	conf, err := config.LoadFile(*configFile, *expandEnvVars)
+
+	if err != nil {
+		slog.Error("failed parsing config file", "error", err)
+		os.Exit(1)
+	}
+
+	C = conf
+
+	M, err = mib.Load(conf.Options.MibDirs)
+	if err != nil {
+		slog.Error("failed loading MIB directories", "error", err)
+		os.Exit(1)
+	}
+
+	if err := run(*transport, *addr, parseLevel(*logLevel)); err != nil {
+		panic(err)
+	}
+}
+
+func run(transport, addr string, logLevel slog.Level) error {
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel})))
+	server := mcp.NewServer(&mcp.Implementation{Name: "net-snmp-tools", Version: Version()}, &mcp.ServerOptions{})
+
+	mcp.AddTool(server, &mcp.Tool{Name: "net_snmp_get", Description: "The net_snmp_get command is used to retrieve the value of a specific OID (Object Identifier) from an SNMP-enabled device. It performs a single request to fetch the value of one or more explicitly specified OIDs, and is ideal when you know exactly what piece of data you're querying."}, getHandler)
+	mcp.AddTool(server, &mcp.Tool{Name: "net_snmp_walk", Description: "The net_snmp_walk command is used to recursively retrieve a subtree of OIDs from an SNMP agent. It starts from a given root OID and walks down the tree, returning all OIDs and their values beneath it. This is useful for exploring available SNMP data or retrieving entire tables (e.g., interface lists, ARP tables)."}, walkHandler)
+	mcp.AddTool(server, &mcp.Tool{Name: "net_snmp_translate", Description: "The net_snmp_translate command resolves a symbolic MIB name (e.g. 'SNMPv2-MIB::sysDescr.0' or just 'sysDescr.0') to its numeric OID, using the MIB files loaded from the configured mib_dirs. This is the inverse of the MIB name translation applied to net_snmp_get/net_snmp_walk output."}, translateHandler)
+	mcp.AddTool(server, &mcp.Tool{Name: "net_snmp_trap_recent", Description: "The net_snmp_trap_recent command returns the most recently received SNMP traps and informs across all trap listeners configured in the traps section, with variable bindings rendered the same way as net_snmp_get/net_snmp_walk output."}, trapRecentHandler)
+	mcp.AddTool(server, &mcp.Tool{Name: "net_snmp_table", Description: "The net_snmp_table command walks an SNMP table (e.g. ifTable, ipNetToMediaTable) and returns its rows keyed by index with named columns, instead of the flat name=value dump net_snmp_walk produces. This is the structure LLMs need to reason about tabular SNMP data without reconstructing it themselves."}, tableHandler)
+	mcp.AddTool(server, &mcp.Tool{Name: "net_snmp_discover", Description: "The net_snmp_discover command probes a target's well-known system OIDs (sysDescr, sysObjectID, sysUpTime, sysName, entPhysicalDescr, ifNumber) and returns a normalized device profile, including a vendor/module guess and suggested walk roots from the configured profiles section. Useful as a first call against an unfamiliar device before deciding what to get or walk."}, discoverHandler)
+	// trap://recent is a pull resource: the go-sdk v0.2.0 dependency never
+	// dispatches resources/subscribe and never advertises the Subscribe
+	// capability, so there is no server-side hook to push
+	// notifications/resources/updated when TrapRing.Add fires. Clients
+	// get the latest traps by re-reading the resource (or polling
+	// net_snmp_trap_recent); true push notification needs a newer SDK.
+	server.AddResource(&mcp.Resource{
+		URI:         "trap://recent",
+		Name:        "recent-traps",
+		Description: "The most recently received SNMP traps and informs. Pull-only: re-read this resource to see new arrivals, the server cannot push updates with the current SDK.",
+		MIMEType:    "text/plain",
+	}, trapRecentResourceHandler)
+
+	ctx := context.Background()
+	startTrapListeners(ctx)
+
+	if transport == "http" {
+		handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server {
+			return server
+		}, nil)
+		slog.Info("Starting Net-SNMP MCP server using StreamableHTTP transport", "version", Version(), "address", addr)
+		return http.ListenAndServe(addr, handler)
+	} else {
+		slog.Info("Starting Net-SNMP MCP server using stdio transport", "version", Version())
+		return server.Run(ctx, mcp.NewStdioTransport())
+	}
+}
+
+func NewGoSNMP(auth string, target string) (*gosnmp.GoSNMP, error) {
+	transport := "udp"
+	if s := strings.SplitN(target, "://", 2); len(s) == 2 {
+		transport = s[0]
+		target = s[1]
+	}
+	port := uint16(161)
+	if host, _port, err := net.SplitHostPort(target); err == nil {
+		target = host
+		p, err := strconv.Atoi(_port)
+		if err != nil {
+			return nil, fmt.Errorf("failed converting port number to int for target %q: %w", target, err)
+		}
+		port = uint16(p)
+	}
+
+	g := &gosnmp.GoSNMP{
+		ExponentialTimeout: true,
+		MaxOids:            gosnmp.MaxOids,
+		MaxRepetitions:     C.Options.MaxRepetitions,
+		Port:               port,
+		Retries:            *C.Options.Retries,
+		Target:             target,
+		Timeout:            C.Options.Timeout,
+		Transport:          transport,
+	}
+
+	if C.Options.AllowNonIncreasingOIDs {
+		g.AppOpts = map[string]any{
+			"c": true,
+		}
+	}
+
+	cauth, authOk := C.Auths[auth]
+	if authOk {
+		cauth.ConfigureSNMP(g, "")
+	}
+
+	return g, nil
+}
+
+// targetResult is one target's outcome from a fanOut call.
+type targetResult struct {
+	target string
+	text   string
+	err    error
+}
+
+// fanOut runs fn against each target concurrently, bounded by
+// Options.MaxConcurrency, and returns one targetResult per target in the
+// same order. A target that fails doesn't abort the others; its error is
+// carried alongside the rest of the results instead.
+func fanOut(ctx context.Context, targets []string, fn func(ctx context.Context, g *gosnmp.GoSNMP) (string, error), auth string) []targetResult {
+	maxConcurrency := C.Options.MaxConcurrency
+	if maxConcurrency <= 0 || maxConcurrency > len(targets) {
+		maxConcurrency = len(targets)
+	}
+	sem := make(chan struct{}, maxConcurrency)
+
+	results := make([]targetResult, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, target string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			text, err := pollTarget(ctx, auth, target, fn)
+			results[i] = targetResult{target: target, text: text, err: err}
+		}(i, target)
+	}
+	wg.Wait()
+	return results
+}
+
+// pollTarget connects to a single target and runs fn against it, bounded
+// by Options.PerTargetTimeout. Canceling ctx (client disconnect, the
+// per-target deadline) closes the connection to unblock any in-flight
+// read.
+func pollTarget(ctx context.Context, auth, target string, fn func(ctx context.Context, g *gosnmp.GoSNMP) (string, error)) (string, error) {
+	g, err := NewGoSNMP(auth, target)
+	if err != nil {
+		return "", fmt.Errorf("failed to create snmp client: '%w'", err)
+	}
+
+	if timeout := C.Options.PerTargetTimeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	slog.Debug("Connect()", "target", g.Target, "version", g.Version)
+	if err := g.Connect(); err != nil {
+		slog.Error("Connect()", "target", g.Target, "version", g.Version, "error", err)
+		return "", fmt.Errorf("failed connecting to target %s: %s", g.Target, err)
+	}
+	defer g.Conn.Close()
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			g.Conn.Close()
+		case <-done:
+		}
+	}()
+	defer close(done)
+
+	return fn(ctx, g)
+}
+
+// renderTargetResults joins per-target outcomes into a single block of
+// text, one "--- target ---" section per target, with failures reported
+// inline rather than raised.
+func renderTargetResults(results []targetResult) string {
+	var sb strings.Builder
+	for _, r := range results {
+		fmt.Fprintf(&sb, "--- %s ---\n", r.target)
+		if r.err != nil {
+			fmt.Fprintf(&sb, "error: %s\n", r.err)
+			continue
+		}
+		sb.WriteString(r.text)
+	}
+	return sb.String()
+}
@@ -0,0 +1,389 @@
+// Package mib provides a pragmatic parser for MIB modules and a
+// bidirectional OID<->name trie used to translate numeric OIDs into the
+// symbolic names LLM callers usually reason in, and back again.
+//
+// This is not a full SMI/ASN.1 parser: it recognizes the common
+// OBJECT-TYPE / OBJECT-IDENTITY / MODULE-IDENTITY / "OBJECT IDENTIFIER ::="
+// assignment forms that make up the vast majority of real-world MIBs, and
+// resolves them against the well-known RFC1155-SMI roots. Anything it
+// can't parse is silently skipped rather than treated as an error, since a
+// mib_dirs tree commonly contains vendor files with constructs this parser
+// doesn't understand.
+package mib
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// wellKnownRoots seeds the resolver with the standard RFC1155-SMI /
+// RFC1213-MIB names that most MIB files reference but don't define
+// themselves.
+var wellKnownRoots = map[string]string{
+	"iso":            "1",
+	"org":            "1.3",
+	"dod":            "1.3.6",
+	"internet":       "1.3.6.1",
+	"directory":      "1.3.6.1.1",
+	"mgmt":           "1.3.6.1.2",
+	"mib-2":          "1.3.6.1.2.1",
+	"system":         "1.3.6.1.2.1.1",
+	"interfaces":     "1.3.6.1.2.1.2",
+	"transmission":   "1.3.6.1.2.1.10",
+	"snmp":           "1.3.6.1.2.1.11",
+	"experimental":   "1.3.6.1.3",
+	"private":        "1.3.6.1.4",
+	"enterprises":    "1.3.6.1.4.1",
+	"security":       "1.3.6.1.5",
+	"snmpV2":         "1.3.6.1.6",
+	"snmpDomains":    "1.3.6.1.6.1",
+	"snmpProxys":     "1.3.6.1.6.2",
+	"snmpModules":    "1.3.6.1.6.3",
+	"snmpMIBObjects": "1.3.6.1.6.3.1",
+}
+
+var (
+	reModuleHeader = regexp.MustCompile(`^(\S[\w-]*)\s+DEFINITIONS\b`)
+	reDecl         = regexp.MustCompile(`^([a-zA-Z][\w-]*)\s+(OBJECT-TYPE|OBJECT-IDENTITY|MODULE-IDENTITY|NOTIFICATION-TYPE|TRAP-TYPE)\b`)
+	reObjectID     = regexp.MustCompile(`^([a-zA-Z][\w-]*)\s+OBJECT IDENTIFIER\s*::=\s*\{([^}]+)\}`)
+	reAssign       = regexp.MustCompile(`::=\s*\{([^}]+)\}`)
+	reSubID        = regexp.MustCompile(`([a-zA-Z][\w-]*)\s*\(\s*(\d+)\s*\)|([a-zA-Z][\w-]*)|(\d+)`)
+)
+
+// rawAssign is a not-yet-resolved "name ::= { parent subid }" assignment.
+type rawAssign struct {
+	module string
+	parent string
+	subID  uint32
+}
+
+// entry is a fully resolved node: a name bound to a numeric OID.
+type entry struct {
+	module string
+	name   string
+	oid    string
+}
+
+// node is a trie node keyed by OID sub-identifier.
+type node struct {
+	children map[uint32]*node
+	entry    *entry
+}
+
+// Store holds the parsed MIB tree and caches translations both ways.
+type Store struct {
+	mu      sync.RWMutex
+	root    *node
+	byName  map[string]*entry
+	raw     map[string]rawAssign
+	resolve map[string]string // name -> dotted OID, includes wellKnownRoots
+
+	cacheMu     sync.RWMutex
+	toNameCache map[string]string
+	toOIDCache  map[string]string
+}
+
+// NewStore returns an empty Store seeded with the well-known SMI roots.
+func NewStore() *Store {
+	s := &Store{
+		root:        &node{children: map[uint32]*node{}},
+		byName:      map[string]*entry{},
+		raw:         map[string]rawAssign{},
+		resolve:     map[string]string{},
+		toNameCache: map[string]string{},
+		toOIDCache:  map[string]string{},
+	}
+	for name, oid := range wellKnownRoots {
+		e := &entry{module: "RFC1155-SMI", name: name, oid: oid}
+		s.resolve[name] = oid
+		s.byName[name] = e
+		s.byName[e.module+"::"+name] = e
+		s.insert(oid, e)
+	}
+	return s
+}
+
+// Load parses every MIB file found under dirs and returns the resulting
+// Store. A directory that doesn't exist is skipped rather than treated as
+// fatal, since mib_dirs entries are often optional.
+func Load(dirs []string) (*Store, error) {
+	s := NewStore()
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("reading mib dir %q: %w", dir, err)
+		}
+		for _, e := range entries {
+			if e.IsDir() {
+				continue
+			}
+			if err := s.LoadFile(filepath.Join(dir, e.Name())); err != nil {
+				return nil, fmt.Errorf("parsing mib file %q: %w", filepath.Join(dir, e.Name()), err)
+			}
+		}
+	}
+	s.resolveAll()
+	return s, nil
+}
+
+// LoadFile parses a single MIB module file, adding its assignments to the
+// Store. Resolution is deferred until all files are loaded so that
+// cross-module references work regardless of load order.
+func (s *Store) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	module := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	var pending string
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "--") {
+			continue
+		}
+
+		if m := reModuleHeader.FindStringSubmatch(line); m != nil {
+			module = m[1]
+			continue
+		}
+
+		if m := reObjectID.FindStringSubmatch(line); m != nil {
+			if parent, subID, ok := parseAssignClause(m[2]); ok {
+				s.raw[m[1]] = rawAssign{module: module, parent: parent, subID: subID}
+			}
+			continue
+		}
+
+		if m := reDecl.FindStringSubmatch(line); m != nil {
+			pending = m[1]
+			continue
+		}
+
+		if pending != "" {
+			if m := reAssign.FindStringSubmatch(line); m != nil {
+				if parent, subID, ok := parseAssignClause(m[1]); ok {
+					s.raw[pending] = rawAssign{module: module, parent: parent, subID: subID}
+				}
+				pending = ""
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// parseAssignClause extracts the parent name and final sub-identifier from
+// the contents of a "::= { ... }" clause, e.g. "iso org(3) dod(6)
+// internet(1) mgmt(2) mib-2(1) system 1" -> ("system", 1).
+func parseAssignClause(clause string) (parent string, subID uint32, ok bool) {
+	matches := reSubID.FindAllStringSubmatch(clause, -1)
+	if len(matches) < 2 {
+		return "", 0, false
+	}
+	last := matches[len(matches)-1]
+	var numStr string
+	switch {
+	case last[2] != "":
+		numStr = last[2]
+	case last[4] != "":
+		numStr = last[4]
+	default:
+		return "", 0, false
+	}
+	n, err := strconv.ParseUint(numStr, 10, 32)
+	if err != nil {
+		return "", 0, false
+	}
+
+	prev := matches[len(matches)-2]
+	switch {
+	case prev[1] != "":
+		parent = prev[1]
+	case prev[3] != "":
+		parent = prev[3]
+	default:
+		return "", 0, false
+	}
+	return parent, uint32(n), true
+}
+
+// resolveAll walks every raw assignment to a fully resolved dotted OID and
+// inserts it into the trie. Unresolvable assignments (unknown parent,
+// cycles) are skipped.
+func (s *Store) resolveAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(s.raw))
+	for name := range s.raw {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	visiting := map[string]bool{}
+	for _, name := range names {
+		oid, ok := s.resolveName(name, visiting)
+		if !ok {
+			continue
+		}
+		ra := s.raw[name]
+		e := &entry{module: ra.module, name: name, oid: oid}
+		s.byName[name] = e
+		s.byName[ra.module+"::"+name] = e
+		s.insert(oid, e)
+	}
+}
+
+func (s *Store) resolveName(name string, visiting map[string]bool) (string, bool) {
+	if oid, ok := s.resolve[name]; ok {
+		return oid, true
+	}
+	ra, ok := s.raw[name]
+	if !ok || visiting[name] {
+		return "", false
+	}
+	visiting[name] = true
+	defer delete(visiting, name)
+
+	parentOID, ok := s.resolveName(ra.parent, visiting)
+	if !ok {
+		return "", false
+	}
+	oid := parentOID + "." + strconv.FormatUint(uint64(ra.subID), 10)
+	s.resolve[name] = oid
+	return oid, true
+}
+
+func (s *Store) insert(oid string, e *entry) {
+	parts := strings.Split(oid, ".")
+	n := s.root
+	for _, p := range parts {
+		id, err := strconv.ParseUint(p, 10, 32)
+		if err != nil {
+			return
+		}
+		child, ok := n.children[uint32(id)]
+		if !ok {
+			child = &node{children: map[uint32]*node{}}
+			n.children[uint32(id)] = child
+		}
+		n = child
+	}
+	n.entry = e
+}
+
+// Translate maps a numeric OID (optionally dot-prefixed, optionally with a
+// trailing instance index) to its symbolic "Module::name.instance" form.
+// If no known MIB object matches any prefix of oid, oid is returned in
+// its numeric, dot-prefixed form.
+func (s *Store) Translate(oid string) string {
+	trimmed := strings.TrimPrefix(oid, ".")
+
+	s.cacheMu.RLock()
+	if cached, ok := s.toNameCache[trimmed]; ok {
+		s.cacheMu.RUnlock()
+		return cached
+	}
+	s.cacheMu.RUnlock()
+
+	s.mu.RLock()
+	result := s.translateLocked(trimmed)
+	s.mu.RUnlock()
+
+	s.cacheMu.Lock()
+	s.toNameCache[trimmed] = result
+	s.cacheMu.Unlock()
+	return result
+}
+
+func (s *Store) translateLocked(trimmed string) string {
+	parts := strings.Split(trimmed, ".")
+	n := s.root
+	var best *entry
+	var bestDepth int
+	for i, p := range parts {
+		id, err := strconv.ParseUint(p, 10, 32)
+		if err != nil {
+			break
+		}
+		child, ok := n.children[uint32(id)]
+		if !ok {
+			break
+		}
+		n = child
+		if n.entry != nil {
+			best = n.entry
+			bestDepth = i + 1
+		}
+	}
+	if best == nil {
+		return "." + trimmed
+	}
+	name := best.module + "::" + best.name
+	if rest := parts[bestDepth:]; len(rest) > 0 {
+		name += "." + strings.Join(rest, ".")
+	}
+	return name
+}
+
+// Resolve maps a symbolic name (e.g. "sysDescr", "sysDescr.0" or
+// "SNMPv2-MIB::sysDescr.0") back to its numeric OID, preserving any
+// trailing instance suffix. It returns an error if no known MIB object
+// matches any prefix of name.
+func (s *Store) Resolve(name string) (string, error) {
+	s.cacheMu.RLock()
+	if cached, ok := s.toOIDCache[name]; ok {
+		s.cacheMu.RUnlock()
+		return cached, nil
+	}
+	s.cacheMu.RUnlock()
+
+	s.mu.RLock()
+	oid, err := s.resolveLocked(name)
+	s.mu.RUnlock()
+	if err != nil {
+		return "", err
+	}
+
+	s.cacheMu.Lock()
+	s.toOIDCache[name] = oid
+	s.cacheMu.Unlock()
+	return oid, nil
+}
+
+func (s *Store) resolveLocked(name string) (string, error) {
+	bare := name
+	if i := strings.Index(bare, "::"); i >= 0 {
+		bare = bare[i+2:]
+	}
+
+	tokens := strings.Split(bare, ".")
+	for i := len(tokens); i > 0; i-- {
+		candidate := strings.Join(tokens[:i], ".")
+		if e, ok := s.byName[candidate]; ok {
+			oid := e.oid
+			if rest := tokens[i:]; len(rest) > 0 {
+				oid += "." + strings.Join(rest, ".")
+			}
+			return oid, nil
+		}
+	}
+	return "", fmt.Errorf("unknown MIB name: %q", name)
+}
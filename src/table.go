@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type TableParams struct {
+	Auth   string `json:"auth" jsonschema:"Authorization"`
+	OID    string `json:"oid" jsonschema:"Table root OID, e.g. the ifTable OID"`
+	Target string `json:"target" jsonschema:"Target IP or hostname"`
+	Format string `json:"format,omitempty" jsonschema:"Output format: 'json' (default) for an array of rows, or 'text' for an ASCII table"`
+}
+
+// tableRow is one row of a table, keyed by its (possibly multi-subid)
+// index.
+type tableRow struct {
+	Index   string            `json:"index"`
+	Columns map[string]string `json:"columns"`
+}
+
+func tableHandler(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[TableParams]) (*mcp.CallToolResultFor[any], error) {
+	g, err := NewGoSNMP(params.Arguments.Auth, params.Arguments.Target)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snmp client: '%w'", err)
+	}
+
+	slog.Debug("Connect()", "target", g.Target, "version", g.Version)
+	if err := g.Connect(); err != nil {
+		slog.Error("Connect()", "target", g.Target, "version", g.Version, "error", err)
+		return nil, fmt.Errorf("failed connecting to target %s: %s", g.Target, err)
+	}
+	defer g.Conn.Close()
+
+	root := strings.TrimPrefix(params.Arguments.OID, ".")
+	rows := map[string]*tableRow{}
+	var order []string
+
+	slog.Debug("BulkWalk()", "target", g.Target, "OID", params.Arguments.OID)
+	if err := g.BulkWalk(params.Arguments.OID, func(pdu gosnmp.SnmpPDU) error {
+		column, index, ok := splitTableOID(root, pdu.Name)
+		if !ok {
+			return nil
+		}
+
+		row, ok := rows[index]
+		if !ok {
+			row = &tableRow{Index: index, Columns: map[string]string{}}
+			rows[index] = row
+			order = append(order, index)
+		}
+		row.Columns[columnName(column)] = formatPDUValue(pdu)
+		return nil
+	}); err != nil {
+		slog.Error("BulkWalk()", "target", g.Target, "version", g.Version, "error", err)
+		return nil, fmt.Errorf("failed walking table %s on target %s: %s", params.Arguments.OID, g.Target, err)
+	}
+
+	sort.Strings(order)
+	sorted := make([]*tableRow, len(order))
+	for i, index := range order {
+		sorted[i] = rows[index]
+	}
+
+	var text string
+	if params.Arguments.Format == "text" {
+		text = renderTableText(sorted)
+	} else {
+		out, err := json.MarshalIndent(sorted, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to render table: %w", err)
+		}
+		text = string(out)
+	}
+
+	return &mcp.CallToolResultFor[any]{
+		Content: []mcp.Content{&mcp.TextContent{Text: text}},
+	}, nil
+}
+
+// splitTableOID splits a full instance OID returned while walking a table
+// rooted at root into its column OID (root.entry.column) and its index
+// (the remaining sub-identifiers). It assumes the standard SNMP table
+// layout (root -> entry -> column -> index), which covers the tables LLM
+// callers ask about in practice (ifTable, ipNetToMediaTable, etc.); it does
+// not consult MIB INDEX clauses, so compound indexes are simply rendered
+// as dotted strings rather than split into their component values.
+func splitTableOID(root, name string) (column, index string, ok bool) {
+	trimmed := strings.TrimPrefix(name, ".")
+	prefix := root + "."
+	if !strings.HasPrefix(trimmed, prefix) {
+		return "", "", false
+	}
+
+	tokens := strings.Split(strings.TrimPrefix(trimmed, prefix), ".")
+	if len(tokens) < 3 {
+		return "", "", false
+	}
+
+	column = root + "." + tokens[0] + "." + tokens[1]
+	index = strings.Join(tokens[2:], ".")
+	return column, index, true
+}
+
+// columnName resolves a column OID to its MIB name (without the module
+// prefix). If the column itself is unknown, the result is the nearest known
+// ancestor's name followed by the remaining sub-identifiers; the numeric
+// OID is returned only when no known name covers it.
+func columnName(columnOID string) string {
+	translated := M.Translate(columnOID)
+	if strings.Contains(translated, "::") {
+		if i := strings.Index(translated, "::"); i >= 0 {
+			return translated[i+2:]
+		}
+	}
+	return columnOID
+}
+
+func renderTableText(rows []*tableRow) string {
+	var columns []string
+	seen := map[string]bool{}
+	for _, row := range rows {
+		for col := range row.Columns {
+			if !seen[col] {
+				seen[col] = true
+				columns = append(columns, col)
+			}
+		}
+	}
+	sort.Strings(columns)
+
+	widths := make([]int, len(columns)+1)
+	widths[0] = len("index")
+	for i, col := range columns {
+		widths[i+1] = len(col)
+	}
+	for _, row := range rows {
+		widths[0] = max(widths[0], len(row.Index))
+		for i, col := range columns {
+			widths[i+1] = max(widths[i+1], len(row.Columns[col]))
+		}
+	}
+
+	var sb strings.Builder
+	writeRow := func(cells []string) {
+		for i, cell := range cells {
+			fmt.Fprintf(&sb, "%-*s  ", widths[i], cell)
+		}
+		sb.WriteString("\n")
+	}
+
+	header := append([]string{"index"}, columns...)
+	writeRow(header)
+	for _, row := range rows {
+		cells := make([]string, len(columns)+1)
+		cells[0] = row.Index
+		for i, col := range columns {
+			cells[i+1] = row.Columns[col]
+		}
+		writeRow(cells)
+	}
+	return sb.String()
+}
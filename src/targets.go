@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+)
+
+// Targets accepts either a single target (a string) or a list of targets
+// in the tool's JSON arguments, and expands any CIDR entries (e.g.
+// "192.0.2.0/24") into their individual host addresses.
+type Targets []string
+
+func (t *Targets) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		return t.set([]string{single})
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("target must be a string or an array of strings: %w", err)
+	}
+	return t.set(list)
+}
+
+func (t *Targets) set(raw []string) error {
+	var expanded []string
+	for _, r := range raw {
+		hosts, err := expandCIDR(r)
+		if err != nil {
+			return err
+		}
+		expanded = append(expanded, hosts...)
+	}
+	*t = expanded
+	return nil
+}
+
+// expandCIDR returns the individual host addresses covered by target if it
+// is in CIDR notation, or []string{target} unchanged otherwise. Network
+// and broadcast addresses are skipped for IPv4 ranges of /30 or wider;
+// /31 and /32 keep every address.
+func expandCIDR(target string) ([]string, error) {
+	ip, ipNet, err := net.ParseCIDR(target)
+	if err != nil {
+		return []string{target}, nil
+	}
+
+	var hosts []string
+	for cur := ip.Mask(ipNet.Mask); ipNet.Contains(cur); incIP(cur) {
+		hosts = append(hosts, cur.String())
+	}
+
+	ones, bits := ipNet.Mask.Size()
+	if bits == 32 && bits-ones >= 2 && len(hosts) >= 2 {
+		hosts = hosts[1 : len(hosts)-1] // drop network and broadcast addresses
+	}
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("CIDR %q contains no usable addresses", target)
+	}
+	return hosts, nil
+}
+
+func incIP(ip net.IP) {
+	for i := len(ip) - 1; i >= 0; i-- {
+		ip[i]++
+		if ip[i] != 0 {
+			break
+		}
+	}
+}
@@ -16,90 +16,199 @@ import (
 type GetParams struct {
 	Auth   string   `json:"auth" jsonschema:"Authorization"`
 	OIDs   []string `json:"oids" jsonschema:"OID(s) to get"`
-	Target string   `json:"target" jsonschema:"Target IP or hostname"`
+	Target Targets  `json:"target" jsonschema:"Target IP or hostname, a list of them, or a CIDR range to fan out to"`
 }
 
 type WalkParams struct {
-	Auth   string `json:"auth" jsonschema:"Authorization"`
-	OID    string `json:"oid" jsonschema:"Root OID to walk"`
-	Target string `json:"target" jsonschema:"Target IP or hostname"`
+	Auth   string  `json:"auth" jsonschema:"Authorization"`
+	OID    string  `json:"oid" jsonschema:"Root OID to walk"`
+	Target Targets `json:"target" jsonschema:"Target IP or hostname, a list of them, or a CIDR range to fan out to"`
+	// Mode selects the request type driving the walk: "bulkwalk" (GETBULK,
+	// the default), "walk" (GETNEXT, one request per value), or "getnext"
+	// (a single GETNEXT request against OID).
+	Mode string `json:"mode,omitempty" jsonschema:"Walk mode: bulkwalk (default), walk, or getnext"`
+	// MaxRepetitions overrides Options.max_repetitions for this call. Only
+	// meaningful for mode bulkwalk.
+	MaxRepetitions uint32 `json:"max_repetitions,omitempty" jsonschema:"GETBULK max-repetitions for this call, overriding the configured default"`
+	// NonRepeaters overrides GETBULK's non-repeaters for this call. Only
+	// meaningful for mode bulkwalk; a value above 0 routes the walk
+	// through a dedicated loop since gosnmp's BulkWalk always sends 0.
+	// Because the walk only ever has one root OID, there are no repeater
+	// varbinds left to bulk once the non-repeaters are consumed, so this
+	// degrades the whole walk to one GETBULK round-trip per value.
+	NonRepeaters int `json:"non_repeaters,omitempty" jsonschema:"GETBULK non-repeaters for this call; a single-root walk has no repeater varbinds to pair it with, so any non-zero value forces the entire walk into a slow GETNEXT-style round-trip per value"`
+	// AllowNonIncreasingOIDs, when true, enables
+	// Options.allow_nonincreasing_oids for this call, letting a caller
+	// retry a walk against an agent known to return non-increasing OIDs
+	// without editing the config file. False leaves the configured value.
+	AllowNonIncreasingOIDs bool `json:"allow_non_increasing_oids,omitempty" jsonschema:"Tolerate non-increasing OIDs from a broken agent for this call, overriding the configured default"`
 }
 
-func getHandler(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[GetParams]) (*mcp.CallToolResultFor[any], error) {
-	g, err := NewGoSNMP(params.Arguments.Auth, params.Arguments.Target)
+type TranslateParams struct {
+	Name string `json:"name" jsonschema:"Symbolic MIB name to resolve, e.g. SNMPv2-MIB::sysDescr.0 or sysDescr.0"`
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to create snmp client: '%w'", err)
+func getHandler(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[GetParams]) (*mcp.CallToolResultFor[any], error) {
+	if len(params.Arguments.Target) == 0 {
+		return nil, fmt.Errorf("target is required")
 	}
 
-	slog.Debug("Connect()", "target", g.Target, "version", g.Version)
-	if err := g.Connect(); err != nil {
-		slog.Error("Connect()", "target", g.Target, "version", g.Version, "error", err)
-		return nil, fmt.Errorf("failed connecting to target %s: %s", g.Target, err)
-	}
-	defer g.Conn.Close()
+	results := fanOut(ctx, params.Arguments.Target, func(ctx context.Context, g *gosnmp.GoSNMP) (string, error) {
+		slog.Debug("Get()", "target", g.Target, "OID(s)", params.Arguments.OIDs)
+		res, err := g.Get(params.Arguments.OIDs)
+		if err != nil {
+			slog.Error("Get()", "target", g.Target, "version", g.Version, "error", err)
+			return "", fmt.Errorf("failed getting target %s: %s", g.Target, err)
+		}
 
-	slog.Debug("Get()", "target", g.Target, "OID(s)", params.Arguments.OIDs)
-	res, err := g.Get(params.Arguments.OIDs)
+		var sb strings.Builder
+		for _, pdu := range res.Variables {
+			formatValue(&sb, pdu)
+		}
+		return sb.String(), nil
+	}, params.Arguments.Auth)
 
-	if err != nil {
-		slog.Error("Get()", "target", g.Target, "version", g.Version, "error", err)
-		return nil, fmt.Errorf("failed getting target %s: %s", g.Target, err)
-	}
+	return &mcp.CallToolResultFor[any]{
+		Content: []mcp.Content{&mcp.TextContent{Text: renderTargetResults(results)}},
+	}, nil
+}
 
-	var sb strings.Builder
+func walkHandler(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[WalkParams]) (*mcp.CallToolResultFor[any], error) {
+	if len(params.Arguments.Target) == 0 {
+		return nil, fmt.Errorf("target is required")
+	}
 
-	for _, pdu := range res.Variables {
-		formatValue(&sb, pdu)
+	mode := params.Arguments.Mode
+	if mode == "" {
+		mode = "bulkwalk"
 	}
 
+	results := fanOut(ctx, params.Arguments.Target, func(ctx context.Context, g *gosnmp.GoSNMP) (string, error) {
+		if params.Arguments.MaxRepetitions > 0 {
+			g.MaxRepetitions = params.Arguments.MaxRepetitions
+		}
+		if params.Arguments.AllowNonIncreasingOIDs {
+			g.AppOpts = map[string]any{"c": true}
+		}
+
+		var sb strings.Builder
+		walkFn := func(pdu gosnmp.SnmpPDU) error {
+			formatValue(&sb, pdu)
+			return nil
+		}
+
+		var err error
+		switch mode {
+		case "walk":
+			slog.Debug("Walk()", "target", g.Target, "OID", params.Arguments.OID)
+			err = g.Walk(params.Arguments.OID, walkFn)
+		case "getnext":
+			slog.Debug("GetNext()", "target", g.Target, "OID", params.Arguments.OID)
+			var res *gosnmp.SnmpPacket
+			res, err = g.GetNext([]string{params.Arguments.OID})
+			if err == nil {
+				for _, pdu := range res.Variables {
+					formatValue(&sb, pdu)
+				}
+			}
+		default:
+			if params.Arguments.NonRepeaters > 0 {
+				slog.Debug("bulkWalkN()", "target", g.Target, "OID", params.Arguments.OID, "nonRepeaters", params.Arguments.NonRepeaters)
+				err = bulkWalkN(g, params.Arguments.OID, params.Arguments.NonRepeaters, walkFn)
+			} else {
+				slog.Debug("BulkWalk()", "target", g.Target, "OID", params.Arguments.OID)
+				err = g.BulkWalk(params.Arguments.OID, walkFn)
+			}
+		}
+		if err != nil {
+			slog.Error("walk", "mode", mode, "target", g.Target, "version", g.Version, "error", err)
+			return "", fmt.Errorf("failed walking target %s: %s", g.Target, err)
+		}
+		return sb.String(), nil
+	}, params.Arguments.Auth)
+
 	return &mcp.CallToolResultFor[any]{
-		Content: []mcp.Content{&mcp.TextContent{Text: sb.String()}},
+		Content: []mcp.Content{&mcp.TextContent{Text: renderTargetResults(results)}},
 	}, nil
 }
 
-func walkHandler(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[WalkParams]) (*mcp.CallToolResultFor[any], error) {
-	g, err := NewGoSNMP(params.Arguments.Auth, params.Arguments.Target)
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to create snmp client: '%w'", err)
+// bulkWalkN walks rootOid using GETBULK requests with a caller-chosen
+// non-repeaters value, which gosnmp's own BulkWalk doesn't expose (it
+// always sends non-repeaters 0). It otherwise mirrors BulkWalk's
+// subtree-boundary and end-of-walk handling.
+func bulkWalkN(g *gosnmp.GoSNMP, rootOid string, nonRepeaters int, walkFn gosnmp.WalkFunc) error {
+	if !strings.HasPrefix(rootOid, ".") {
+		rootOid = "." + rootOid
 	}
 
-	slog.Debug("Connect()", "target", g.Target, "version", g.Version)
-	if err := g.Connect(); err != nil {
-		slog.Error("Connect()", "target", g.Target, "version", g.Version, "error", err)
-		return nil, fmt.Errorf("failed connecting to target %s: %s", g.Target, err)
+	maxReps := g.MaxRepetitions
+	if maxReps == 0 {
+		maxReps = 50
 	}
-	defer g.Conn.Close()
 
-	var sb strings.Builder
+	oid := rootOid
+	for requests := 0; ; requests++ {
+		response, err := g.GetBulk([]string{oid}, uint8(nonRepeaters), maxReps)
+		if err != nil {
+			return err
+		}
+		if len(response.Variables) == 0 {
+			return nil
+		}
+
+		for i, pdu := range response.Variables {
+			if pdu.Type == gosnmp.EndOfMibView || pdu.Type == gosnmp.NoSuchObject || pdu.Type == gosnmp.NoSuchInstance {
+				return nil
+			}
+			if !strings.HasPrefix(pdu.Name, rootOid+".") {
+				if pdu.Name == rootOid && requests == 0 && i == 0 {
+					return walkFn(pdu)
+				}
+				return nil
+			}
+			if err := walkFn(pdu); err != nil {
+				return err
+			}
+		}
+		oid = response.Variables[len(response.Variables)-1].Name
+	}
+}
 
-	slog.Debug("BulkWalk()", "target", g.Target, "OID", params.Arguments.OID)
-	if err := g.BulkWalk(params.Arguments.OID, func(pdu gosnmp.SnmpPDU) error {
-		formatValue(&sb, pdu)
-		return nil
-	}); err != nil {
-		slog.Error("BulkWalk()", "target", g.Target, "version", g.Version, "error", err)
-		return nil, fmt.Errorf("failed walking target %s: %s", g.Target, err)
+func translateHandler(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[TranslateParams]) (*mcp.CallToolResultFor[any], error) {
+	oid, err := M.Resolve(params.Arguments.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to translate %q: %w", params.Arguments.Name, err)
 	}
 
 	return &mcp.CallToolResultFor[any]{
-		Content: []mcp.Content{&mcp.TextContent{Text: sb.String()}},
+		Content: []mcp.Content{&mcp.TextContent{Text: "." + oid}},
 	}, nil
 }
 
 func formatValue(writer io.Writer, pdu gosnmp.SnmpPDU) {
+	fmt.Fprintf(writer, "%s = %s\n", M.Translate(pdu.Name), formatPDUValue(pdu))
+}
+
+// formatPDUValue renders just the "TYPE: value" portion of a PDU, without
+// the leading "name = " prefix, so callers that already know the name
+// (e.g. the table tool, which names columns itself) aren't stuck
+// re-parsing formatValue's output.
+func formatPDUValue(pdu gosnmp.SnmpPDU) string {
 	switch pdu.Type {
 	case gosnmp.Integer:
-		fmt.Fprintf(writer, "%s = INTEGER: %d\n", pdu.Name, pdu.Value)
+		return fmt.Sprintf("INTEGER: %d", pdu.Value)
 	case gosnmp.IPAddress:
-		fmt.Fprintf(writer, "%s = IpAddress: %s\n", pdu.Name, pdu.Value)
+		return fmt.Sprintf("IpAddress: %s", pdu.Value)
 	case gosnmp.NoSuchInstance:
-		fmt.Fprintf(writer, "%s = No Such Instance currently exists at this OID\n", pdu.Name)
+		return "No Such Instance currently exists at this OID"
 	case gosnmp.NoSuchObject:
-		fmt.Fprintf(writer, "%s = No Such Object available on this agent at this OID\n", pdu.Name)
+		return "No Such Object available on this agent at this OID"
 	case gosnmp.ObjectIdentifier:
-		fmt.Fprintf(writer, "%s = OID: %s\n", pdu.Name, pdu.Value)
+		value := fmt.Sprintf("%s", pdu.Value)
+		if oid, ok := pdu.Value.(string); ok {
+			value = M.Translate(oid)
+		}
+		return fmt.Sprintf("OID: %s", value)
 	case gosnmp.OctetString:
 		bytes := pdu.Value.([]byte)
 
@@ -111,17 +220,16 @@ func formatValue(writer io.Writer, pdu gosnmp.SnmpPDU) {
 		}
 
 		if isHex {
-			fmt.Fprintf(writer, "%s = Hex-STRING: % X\n", pdu.Name, bytes)
-		} else {
-			fmt.Fprintf(writer, "%s = STRING: %s\n", pdu.Name, string(bytes))
+			return fmt.Sprintf("Hex-STRING: % X", bytes)
 		}
+		return fmt.Sprintf("STRING: %s", string(bytes))
 	case gosnmp.TimeTicks:
 		duration := time.Duration(gosnmp.ToBigInt(pdu.Value).Int64()*10) * time.Millisecond
-		fmt.Fprintf(writer, "%s = Timeticks: (%d) %.2d days, %.2d:%.2d:%.2d.%.3d\n", pdu.Name, gosnmp.ToBigInt(pdu.Value).Int64(), int64(duration.Hours()/24), int64(math.Mod(duration.Hours(), 24)), int64(math.Mod(duration.Minutes(), 60)), int64(math.Mod(duration.Seconds(), 60)), int64(math.Mod(float64(duration.Milliseconds()), 1000)))
+		return fmt.Sprintf("Timeticks: (%d) %.2d days, %.2d:%.2d:%.2d.%.3d", gosnmp.ToBigInt(pdu.Value).Int64(), int64(duration.Hours()/24), int64(math.Mod(duration.Hours(), 24)), int64(math.Mod(duration.Minutes(), 60)), int64(math.Mod(duration.Seconds(), 60)), int64(math.Mod(float64(duration.Milliseconds()), 1000)))
 	default:
 		// ... or often you're just interested in numeric values.
 		// ToBigInt() will return the Value as a BigInt, for plugging
 		// into your calculations.
-		fmt.Fprintf(writer, "%s = %s: %d\n", pdu.Name, pdu.Type, pdu.Value)
+		return fmt.Sprintf("%s: %d", pdu.Type, pdu.Value)
 	}
 }
@@ -0,0 +1,167 @@
+// Package trap implements an SNMP trap/inform receiver built on top of
+// gosnmp.TrapListener. Received traps are rate-limited per source and kept
+// in a bounded ring buffer with their raw variable bindings; rendering them
+// (e.g. translating trap OIDs to symbolic names) is left to the caller.
+package trap
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// Entry is a single received trap or inform.
+type Entry struct {
+	Time      time.Time
+	Source    string
+	Variables []gosnmp.SnmpPDU
+}
+
+// Ring is a fixed-capacity circular buffer of recently received traps,
+// shared across all configured listeners.
+type Ring struct {
+	mu   sync.Mutex
+	buf  []Entry
+	next int
+	full bool
+}
+
+// NewRing returns a Ring that retains at most capacity entries.
+func NewRing(capacity int) *Ring {
+	return &Ring{buf: make([]Entry, capacity)}
+}
+
+// Add appends an entry, overwriting the oldest one once the ring is full.
+func (r *Ring) Add(e Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.buf[r.next] = e
+	r.next = (r.next + 1) % len(r.buf)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// Recent returns up to n of the most recently added entries, newest last.
+func (r *Ring) Recent(n int) []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	size := r.next
+	if r.full {
+		size = len(r.buf)
+	}
+	if n <= 0 || n > size {
+		n = size
+	}
+
+	out := make([]Entry, n)
+	for i := 0; i < n; i++ {
+		idx := (r.next - n + i + len(r.buf)) % len(r.buf)
+		out[i] = r.buf[idx]
+	}
+	return out
+}
+
+// rateLimiter enforces a simple sliding-window request cap per source
+// address, so a noisy or misbehaving agent can't flood the ring or the
+// MIB translator.
+type rateLimiter struct {
+	mu     sync.Mutex
+	max    int
+	window time.Duration
+	seen   map[string][]time.Time
+}
+
+func newRateLimiter(max int, window time.Duration) *rateLimiter {
+	return &rateLimiter{max: max, window: window, seen: map[string][]time.Time{}}
+}
+
+func (r *rateLimiter) allow(source string, now time.Time) bool {
+	if r.max <= 0 {
+		return true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cutoff := now.Add(-r.window)
+	times := r.seen[source]
+	kept := times[:0]
+	for _, t := range times {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	if len(kept) >= r.max {
+		r.seen[source] = kept
+		return false
+	}
+	r.seen[source] = append(kept, now)
+	return true
+}
+
+// ListenerConfig configures a single trap receiver.
+type ListenerConfig struct {
+	// Address is the UDP address to listen on, e.g. "0.0.0.0:162".
+	Address string
+	// RateLimit caps the number of traps accepted per source address
+	// within RateLimitWindow; 0 disables rate limiting.
+	RateLimit       int
+	RateLimitWindow time.Duration
+}
+
+// Listener receives traps on one UDP address and files them into a Ring.
+type Listener struct {
+	cfg     ListenerConfig
+	tl      *gosnmp.TrapListener
+	ring    *Ring
+	limiter *rateLimiter
+}
+
+// NewListener builds a Listener. g carries the version and community/USM
+// credentials used to validate incoming traps, mirroring how the same
+// Auth configures outbound polling via Auth.ConfigureSNMP.
+func NewListener(cfg ListenerConfig, g *gosnmp.GoSNMP, ring *Ring) *Listener {
+	l := &Listener{
+		cfg:     cfg,
+		ring:    ring,
+		limiter: newRateLimiter(cfg.RateLimit, cfg.RateLimitWindow),
+	}
+
+	tl := gosnmp.NewTrapListener()
+	tl.Params = g
+	tl.OnNewTrap = l.handle
+	l.tl = tl
+	return l
+}
+
+func (l *Listener) handle(packet *gosnmp.SnmpPacket, addr *net.UDPAddr) {
+	source := addr.IP.String()
+	if !l.limiter.allow(source, time.Now()) {
+		return
+	}
+	l.ring.Add(Entry{
+		Time:      time.Now(),
+		Source:    source,
+		Variables: packet.Variables,
+	})
+}
+
+// Listen blocks, receiving traps until ctx is canceled or the listener
+// fails to bind.
+func (l *Listener) Listen(ctx context.Context) error {
+	go func() {
+		<-ctx.Done()
+		l.tl.Close()
+	}()
+
+	if err := l.tl.Listen(l.cfg.Address); err != nil {
+		return fmt.Errorf("trap listener on %s: %w", l.cfg.Address, err)
+	}
+	return nil
+}
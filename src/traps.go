@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"snmp_mcp_server/trap"
+)
+
+// TrapRing holds the most recently received traps across all configured
+// listeners, read by net_snmp_trap_recent and the trap://recent resource.
+var TrapRing = trap.NewRing(1000)
+
+// startTrapListeners starts one goroutine per configured trap listener.
+// Each listener runs until ctx is canceled; bind failures are logged
+// rather than treated as fatal, since other listeners (and polling) should
+// keep working if one trap receiver can't come up.
+func startTrapListeners(ctx context.Context) {
+	if size := C.Options.TrapRingSize; size > 0 {
+		TrapRing = trap.NewRing(size)
+	}
+
+	for _, lc := range C.Traps {
+		lc := lc
+		g, err := NewGoSNMP(lc.Auth, lc.Address)
+		if err != nil {
+			slog.Error("failed to configure trap listener", "address", lc.Address, "error", err)
+			continue
+		}
+
+		listener := trap.NewListener(trap.ListenerConfig{
+			Address:         lc.Address,
+			RateLimit:       lc.RateLimit,
+			RateLimitWindow: lc.RateLimitWindow,
+		}, g, TrapRing)
+
+		go func() {
+			slog.Info("Listen() trap", "address", lc.Address, "version", g.Version)
+			if err := listener.Listen(ctx); err != nil {
+				slog.Error("Listen() trap", "address", lc.Address, "error", err)
+			}
+		}()
+	}
+}
+
+type TrapRecentParams struct {
+	N int `json:"n,omitempty" jsonschema:"Maximum number of recent traps to return; 0 means all retained traps"`
+}
+
+func trapRecentHandler(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[TrapRecentParams]) (*mcp.CallToolResultFor[any], error) {
+	return &mcp.CallToolResultFor[any]{
+		Content: []mcp.Content{&mcp.TextContent{Text: formatTraps(TrapRing.Recent(params.Arguments.N))}},
+	}, nil
+}
+
+func trapRecentResourceHandler(ctx context.Context, cc *mcp.ServerSession, params *mcp.ReadResourceParams) (*mcp.ReadResourceResult, error) {
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{
+			URI:      params.URI,
+			MIMEType: "text/plain",
+			Text:     formatTraps(TrapRing.Recent(0)),
+		}},
+	}, nil
+}
+
+func formatTraps(entries []trap.Entry) string {
+	var sb strings.Builder
+	for _, e := range entries {
+		fmt.Fprintf(&sb, "--- trap from %s at %s ---\n", e.Source, e.Time.Format("2006-01-02T15:04:05Z07:00"))
+		for _, pdu := range e.Variables {
+			formatValue(&sb, pdu)
+		}
+	}
+	return sb.String()
+}